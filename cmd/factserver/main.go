@@ -0,0 +1,124 @@
+// Command factserver is a TCP front-end for the factorial package: each
+// connection can send any number of newline-delimited JSON requests and
+// reads back newline-delimited JSON responses, correlated by id, streamed
+// as they're ready. It demonstrates factorial.Server's array of processes
+// used as a real, concurrently-pipelined service rather than the
+// one-shot CLI in the csp command.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/FrontSide/csp/factorial"
+)
+
+// request is the wire format read from a connection: the same shape as
+// factorial.Request, just with JSON tags.
+type request struct {
+	ID uint64 `json:"id"`
+	N  int    `json:"n"`
+}
+
+// response is the wire format written back to a connection. Result is
+// rendered as a decimal string rather than a JSON number since factorials
+// of even moderately large n overflow float64 and every other JSON numeric
+// type.
+type response struct {
+	ID     uint64 `json:"id"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func main() {
+	addr := flag.String("addr", ":9999", "address to listen on")
+	maxN := flag.Int("max-n", 100000, "reject requests for N above this, since N levels of the array are created to serve one")
+	flag.Parse()
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ln.Close()
+	log.Printf("factserver listening on %s", ln.Addr())
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("accept: %v", err)
+			continue
+		}
+		go handleConn(conn, *maxN)
+	}
+}
+
+// connWriter serializes the writes a connection's two producers - the
+// server's replies and requests handleConn rejects locally - make to the
+// same net.Conn, since json.Encoder isn't safe for concurrent use.
+type connWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (w *connWriter) write(resp response) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(resp)
+}
+
+// handleConn serves one connection against its own factorial.Server: every
+// request the connection sends is submitted as soon as it's decoded, and
+// responses are written back as soon as they arrive, so multiple requests
+// from the same connection are pipelined concurrently through the array of
+// processes instead of being handled one at a time.
+//
+// Unlike the csp command, requests here come from the network rather than
+// an interactive, locally-trusted user, so N is capped at maxN before it
+// ever reaches the array: one level of the array is created per unit of N,
+// and nothing else in factorial.Server bounds how deep that's allowed to go.
+func handleConn(conn net.Conn, maxN int) {
+	defer conn.Close()
+
+	server := factorial.Fact()
+	defer server.Close()
+
+	w := &connWriter{enc: json.NewEncoder(conn)}
+	go writeResponses(w, server.Out)
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if req.N > maxN {
+			if err := w.write(response{ID: req.ID, Error: fmt.Sprintf("n=%d exceeds the server's limit of %d", req.N, maxN)}); err != nil {
+				return
+			}
+			continue
+		}
+		server.In <- factorial.Request{ID: req.ID, N: req.N}
+	}
+}
+
+// writeResponses streams the server's replies to w until out is closed,
+// which happens once handleConn's deferred server.Close has drained any
+// requests still in flight.
+func writeResponses(w *connWriter, out <-chan factorial.Response) {
+	for res := range out {
+		resp := response{ID: res.ID}
+		if res.Err != nil {
+			resp.Error = res.Err.Error()
+		} else {
+			resp.Result = res.Result.String()
+		}
+		if err := w.write(resp); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,392 @@
+// Package factorial implements Hoare's CSP "iterative array" solution for
+// calculating factorials, plus a divide-and-conquer alternative built on
+// the pipeline package. It is consumed by the csp command (an interactive,
+// single-calculation CLI) and by cmd/factserver (a long-running TCP/JSON
+// server), so the array of processes and its goroutines only need to exist
+// in one place.
+package factorial
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/FrontSide/csp/pipeline"
+)
+
+var one = big.NewInt(1)
+
+// span is an inclusive [lo, hi] range of the factors to be multiplied by a
+// single FactParallel worker.
+type span struct {
+	lo, hi int
+}
+
+// spans splits [1, n] into up to workers roughly-even, non-overlapping
+// ranges.
+func spans(n, workers int) []span {
+	if workers > n {
+		workers = n
+	}
+
+	out := make([]span, 0, workers)
+	size := n / workers
+	remainder := n % workers
+
+	lo := 1
+	for i := 0; i < workers; i++ {
+		hi := lo + size - 1
+		if i < remainder {
+			hi++
+		}
+		out = append(out, span{lo: lo, hi: hi})
+		lo = hi + 1
+	}
+
+	return out
+}
+
+// product multiplies every integer in [s.lo, s.hi] and returns the result
+// as a *big.Int.
+func product(s span) *big.Int {
+	p := big.NewInt(1)
+	for i := s.lo; i <= s.hi; i++ {
+		p.Mul(p, big.NewInt(int64(i)))
+	}
+	return p
+}
+
+// multiplySpans is a pipeline.Stage that turns spans into their products, so
+// FactParallel's workers can be run through pipeline.FanOut.
+func multiplySpans(ctx context.Context, in <-chan span) <-chan *big.Int {
+	out := make(chan *big.Int)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case s, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- product(s):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+/*FactParallel
+Unlike Fact, this isn't modeled as a CSP iterative array: it's a plain
+divide-and-conquer fan-out/fan-in, the shape pipeline.FanOut and
+pipeline.FanIn were built for. [1, n] is split into workers spans, each
+span's product computed by its own worker via pipeline.FanOut, and the
+partial products merged back through pipeline.FanIn, whose goroutines
+already form the merge tree - fan-in's whole job is draining many
+producers into one channel. The final reduction over the merged channel
+is a simple running multiplication; since multiplication is
+commutative and associative, the order partial products arrive in
+doesn't matter.
+
+workers <= 0 defaults to runtime.NumCPU().*/
+func FactParallel(n, workers int) *big.Int {
+	if n < 2 {
+		return big.NewInt(1)
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx := context.Background()
+
+	in := make(chan span)
+	ss := spans(n, workers)
+	go func() {
+		defer close(in)
+		for _, s := range ss {
+			in <- s
+		}
+	}()
+
+	outs := pipeline.FanOut(ctx, len(ss), in, pipeline.Stage[span, *big.Int](multiplySpans))
+	merged := pipeline.FanIn(ctx, outs...)
+
+	result := big.NewInt(1)
+	for p := range merged {
+		result.Mul(result, p)
+	}
+	return result
+}
+
+// job is what travels between levels of the array while a Request is being
+// computed. Levels are shared by however many Requests are in flight at
+// once, so each job carries the id of the Request it belongs to and a
+// replyTo channel of its own: a level only ever writes jobs forward onto
+// the channel it hands to the next level, never back onto the channel it
+// read from, so the reply has to travel its own way back up.
+type job struct {
+	id      uint64
+	n       *big.Int
+	replyTo chan *big.Int
+}
+
+// array is the "array of processes" from Hoare's paper (see Fact's doc
+// comment below), grown lazily one level at a time instead of being
+// pre-sized to a constant: a request for n only ever needs n levels, so
+// memory stays proportional to the deepest request seen so far rather than
+// to some fixed ceiling. fac is guarded by mu because ensure can be called
+// by more than one level's goroutine, and once requests are pipelined
+// concurrently, by more than one in-flight request.
+type array struct {
+	ctx context.Context
+
+	mu  sync.Mutex
+	fac []<-chan job
+}
+
+// ensure returns the channel at depth i, creating it - and the
+// pipeline.Stage that serves it, fed by prev - if it doesn't exist yet.
+func (a *array) ensure(i int, prev <-chan job) <-chan job {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if i < len(a.fac) {
+		return a.fac[i]
+	}
+
+	self := a.levelStage(i)(a.ctx, prev)
+	a.fac = append(a.fac, self)
+	return self
+}
+
+// levelStage returns the pipeline.Stage run at depth i of the array: it
+// reads jobs from in and, past the inflection point, passes the
+// decremented job on to the channel it returns, growing the array by one
+// more level the first time that channel is needed. That's a genuine
+// one-directional in -> out hop, unlike the int-keyed array Fact started
+// from, because the reply for a job travels back over its own dedicated
+// job.replyTo channel instead of back over in or out - splitting the
+// descent from the reply is what makes the recursion expressible as a
+// pipeline.Stage at all.
+//
+// A level never blocks on a deeper level's reply itself: the inflection
+// case is answered inline, but recursing one level deeper is handed off to
+// its own goroutine (one per in-flight job at this level) so the level's
+// loop can go straight back to accepting the next job instead of being
+// pinned to this one request for however long its full round trip down and
+// back takes. That's what lets many Requests be pipelined through the same
+// array at once.
+func (a *array) levelStage(i int) pipeline.Stage[job, job] {
+	return func(ctx context.Context, in <-chan job) <-chan job {
+		out := make(chan job)
+
+		go func() {
+			for {
+				var j job
+				select {
+				case j = <-in:
+				case <-ctx.Done():
+					return
+				}
+
+				if j.n.Cmp(one) <= 0 {
+					// We have reached the inflection point
+					// of the recursion i.e. the depth we
+					// need to calculate the factorial.
+					// Reply with 1.
+					select {
+					case j.replyTo <- big.NewInt(1):
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+
+				// Grow the array by one level if we haven't
+				// recursed this deep before.
+				a.ensure(i+1, out)
+
+				go func(j job) {
+					reply := make(chan *big.Int, 1)
+
+					select {
+					case out <- job{id: j.id, n: new(big.Int).Sub(j.n, one), replyTo: reply}:
+					case <-ctx.Done():
+						return
+					}
+
+					var r *big.Int
+					select {
+					case r = <-reply:
+					case <-ctx.Done():
+						return
+					}
+
+					// Multiply the number coming from the
+					// level prior to ours (j.n) with the
+					// number from the level below (r) and
+					// reply with the product.
+					select {
+					case j.replyTo <- new(big.Int).Mul(j.n, r):
+					case <-ctx.Done():
+						return
+					}
+				}(j)
+			}
+		}()
+
+		return out
+	}
+}
+
+// Request asks a Server to calculate N!. ID is chosen by the caller and
+// echoed back on the matching Response so callers can tell which request a
+// Response answers, even though many may be in flight at once.
+type Request struct {
+	ID uint64 `json:"id"`
+	N  int    `json:"n"`
+}
+
+// Response is a Server's reply to the Request with the same ID. Err is set
+// instead of Result if the request couldn't be computed (for example a
+// negative N).
+type Response struct {
+	ID     uint64
+	Result *big.Int
+	Err    error
+}
+
+// Server exposes the array of processes as a long-running request/response
+// service: submit Requests on In, and receive the matching Responses (by
+// ID, not necessarily in submission order) on Out. Call Close when done
+// with the Server to stop its goroutines.
+type Server struct {
+	In    chan Request
+	Out   chan Response
+	Close func()
+}
+
+/*Fact
+This is an implementation of the solution for recursively
+calculating a factorial presented in the whitepaper
+"Communicating Sequnetial Processes" from C.A.R. Hoare 1978.
+(https://spinroot.com/courses/summer/Papers/hoare_1978.pdf)
+
+The solution in the paper introduced a concept described as
+"iterative arrays" which is an array of processes whereas
+processes in the array can communicate with their neighbouring
+processes.
+For a better explanation (from someone else) check out this
+implementation of the same problem solution:
+https://github.com/thomas11/csp/blob/master/csp.go#L236
+
+Fact starts the array of processes and returns a Server for driving it.
+Every level of the array is itself a pipeline.Stage (see array.levelStage):
+job.replyTo carries each level's reply back up independently of the
+channel the next level was handed, so the descent is a genuine
+one-directional in -> out hop rather than the duplex, same-channel
+request/reply this array started out as. Every stage selects on an
+internal context following the cancellation convention of the pipeline
+package, so calling the returned Server's Close stops every goroutine
+instead of leaking them for the life of the program, which the original
+implementation did.
+
+The element type is *big.Int rather than int, so factorials of
+thousands don't silently overflow, and the array grows lazily one
+level at a time as the recursion actually descends, rather than being
+pre-sized to a constant depth. Requests are correlated by ID rather
+than each level being reserved for a single caller's whole round trip,
+so any number of Requests can be pipelined through the same array of
+processes concurrently.*/
+func Fact() *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &array{ctx: ctx}
+
+	// userProcess is our pipe to the array, i.e. the channel level 1
+	// reads requests from and replies on.
+	userProcess := make(chan job)
+	a.fac = append(a.fac, userProcess)
+
+	// Level 1 is always needed to read requests, so start it eagerly;
+	// every level after it is created on demand by ensure.
+	a.ensure(1, userProcess)
+
+	in := make(chan Request)
+	out := make(chan Response)
+	var pending sync.WaitGroup
+
+	// done closes once the dispatch loop below has returned, i.e. once
+	// it's guaranteed no further pending.Add can happen. Without this,
+	// the shutdown goroutine's pending.Wait could run concurrently with
+	// a pending.Add still in flight: ctx.Done() and a req arriving on in
+	// can become ready at the same instant, and select is free to take
+	// the req case even after Close has been called.
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case req, ok := <-in:
+				if !ok {
+					return
+				}
+				pending.Add(1)
+				go func() {
+					defer pending.Done()
+					dispatch(ctx, userProcess, out, req)
+				}()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Close out once the dispatch loop is done adding work and every
+	// dispatched request has replied or given up, so that callers
+	// ranging over it (like cmd/factserver) stop rather than blocking
+	// forever after Close.
+	go func() {
+		<-ctx.Done()
+		<-done
+		pending.Wait()
+		close(out)
+	}()
+
+	return &Server{In: in, Out: out, Close: cancel}
+}
+
+// dispatch submits req to the array and forwards the matching reply to out
+// as a Response, once it arrives.
+func dispatch(ctx context.Context, userProcess chan job, out chan Response, req Request) {
+	if req.N < 0 {
+		select {
+		case out <- Response{ID: req.ID, Err: fmt.Errorf("factorial of negative number %d is undefined", req.N)}:
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	reply := make(chan *big.Int, 1)
+	select {
+	case userProcess <- job{id: req.ID, n: big.NewInt(int64(req.N)), replyTo: reply}:
+	case <-ctx.Done():
+		return
+	}
+
+	select {
+	case r := <-reply:
+		select {
+		case out <- Response{ID: req.ID, Result: r}:
+		case <-ctx.Done():
+		}
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,135 @@
+package factorial
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// factSequential is the textbook baseline: a single goroutine, no channels,
+// no synchronization. It exists only so the benchmarks below can show what
+// the CSP array and FactParallel are actually buying (or costing) relative
+// to doing the multiplication in a straight line.
+func factSequential(n int) *big.Int {
+	p := big.NewInt(1)
+	for i := 2; i <= n; i++ {
+		p.Mul(p, big.NewInt(int64(i)))
+	}
+	return p
+}
+
+func TestFactParallel(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 5, 13, 100} {
+		want := factSequential(n)
+		got := FactParallel(n, 4)
+		if got.Cmp(want) != 0 {
+			t.Errorf("FactParallel(%d, 4) = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := Fact()
+	defer server.Close()
+
+	ns := []int{0, 1, 5, 13, 25, 10, 2, 8}
+
+	for i, n := range ns {
+		server.In <- Request{ID: uint64(i), N: n}
+	}
+
+	got := make(map[uint64]Response, len(ns))
+	for range ns {
+		res := <-server.Out
+		got[res.ID] = res
+	}
+
+	for i, n := range ns {
+		res, ok := got[uint64(i)]
+		if !ok {
+			t.Fatalf("no response for request %d", i)
+		}
+		if res.Err != nil {
+			t.Fatalf("request %d (N=%d): %v", i, n, res.Err)
+		}
+		if want := factSequential(n); res.Result.Cmp(want) != 0 {
+			t.Errorf("request %d (N=%d) = %s, want %s", i, n, res.Result, want)
+		}
+	}
+}
+
+func TestServerNegativeN(t *testing.T) {
+	server := Fact()
+	defer server.Close()
+
+	server.In <- Request{ID: 1, N: -1}
+	res := <-server.Out
+	if res.Err == nil {
+		t.Fatal("expected an error for N = -1, got none")
+	}
+}
+
+// TestServerConcurrentSubmitAndClose exercises submitting to a Server at
+// the same time something else calls Close, which is the race the shutdown
+// goroutine's pending.Wait has to be safe against: a req arriving on In can
+// still be selected after ctx.Done() fires, so the dispatch loop's
+// pending.Add(1) and Close's eventual pending.Wait must never overlap.
+// Run with -race, this reproduces the race immediately without the done
+// channel synchronizing the two.
+func TestServerConcurrentSubmitAndClose(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		server := Fact()
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				select {
+				case server.In <- Request{ID: uint64(j), N: 5}:
+				default:
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			server.Close()
+		}()
+
+		wg.Wait()
+
+		// Out must still close promptly once Close has been called,
+		// whether or not any of the above sends landed.
+		for range server.Out {
+		}
+	}
+}
+
+func BenchmarkFactCSP(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		server := Fact()
+		server.In <- Request{ID: uint64(i), N: 100}
+		<-server.Out
+		server.Close()
+	}
+}
+
+func BenchmarkFactSequential(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		factSequential(100)
+	}
+}
+
+func BenchmarkFactParallel(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		FactParallel(100, 0)
+	}
+}
+
+func BenchmarkFactParallelLarge(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		FactParallel(20000, 0)
+	}
+}
@@ -0,0 +1,50 @@
+// Command csp is an interactive CLI wrapping the factorial package: it
+// reads one number from stdin, calculates its factorial via the CSP array
+// of processes, and prints the result. See cmd/factserver for a
+// long-running server exposing the same array over the network.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/FrontSide/csp/factorial"
+)
+
+func main() {
+
+	// We let the user enter a number of which we will
+	// calculate the factorial through above recursion or
+	// "iterative array".
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Calculate factorial of: ")
+
+	userInRaw, err := reader.ReadString('\n')
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	userIn, err := strconv.Atoi(strings.TrimSpace(userInRaw))
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	// factorial.Fact starts the array of processes and gives us a
+	// Server to drive it. Close stops the array's goroutines once
+	// main returns.
+	server := factorial.Fact()
+	defer server.Close()
+
+	server.In <- factorial.Request{ID: 1, N: userIn}
+	res := <-server.Out
+
+	if res.Err != nil {
+		log.Fatal(res.Err)
+	}
+
+	fmt.Printf("Fin: %d! = %s", userIn, res.Result.String())
+}
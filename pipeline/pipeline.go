@@ -0,0 +1,83 @@
+// Package pipeline provides small, typed building blocks for assembling
+// concurrent multi-stage pipelines out of plain functions, modeled on the
+// generator / fan-out / fan-in patterns described in
+// https://go.dev/blog/pipelines and https://go.dev/blog/pipelines#fan-out-fan-in.
+//
+// Every stage accepts a context.Context so that cancellation propagates
+// through an entire pipeline: once the context is done, each stage stops
+// sending on its output channel and exits its goroutine(s) instead of
+// blocking forever.
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// Stage is a single step in a pipeline. It reads values of type T from in
+// and returns a channel of values of type U. Implementations must select on
+// ctx.Done() so that they stop producing and their goroutine(s) exit once
+// the context is cancelled, rather than leaking.
+type Stage[T, U any] func(ctx context.Context, in <-chan T) <-chan U
+
+// Chain runs a sequence of same-typed stages one after another, feeding the
+// output of each stage into the next as input. It is the composition of the
+// "generator -> stage -> stage -> ..." shape from the pipelines article.
+func Chain[T any](ctx context.Context, in <-chan T, stages ...Stage[T, T]) <-chan T {
+	out := in
+	for _, stage := range stages {
+		out = stage(ctx, out)
+	}
+	return out
+}
+
+// FanOut starts n independent copies of stage, all reading from the same in
+// channel and each writing to its own output channel. Use FanIn to merge the
+// results back into a single channel. This is the "fan-out" half of
+// fan-out/fan-in: spread the work coming from one channel across multiple
+// goroutines so independent items can be processed in parallel.
+func FanOut[T, U any](ctx context.Context, n int, in <-chan T, stage Stage[T, U]) []<-chan U {
+	outs := make([]<-chan U, n)
+	for i := 0; i < n; i++ {
+		outs[i] = stage(ctx, in)
+	}
+	return outs
+}
+
+// FanIn merges any number of channels of the same element type into a
+// single channel, the counterpart to FanOut. The returned channel is closed
+// once every input channel has been drained, or immediately (after
+// in-flight sends unblock) once ctx is cancelled.
+func FanIn[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+
+	for _, c := range chans {
+		go func(c <-chan T) {
+			defer wg.Done()
+			for {
+				select {
+				case v, ok := <-c:
+					if !ok {
+						return
+					}
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
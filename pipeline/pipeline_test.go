@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func gen(nums ...int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for _, n := range nums {
+			out <- n
+		}
+	}()
+	return out
+}
+
+func double(ctx context.Context, in <-chan int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case n, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- n * 2:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func TestChain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := Chain(ctx, gen(1, 2, 3), double, double)
+
+	var got []int
+	for n := range out {
+		got = append(got, n)
+	}
+	sort.Ints(got)
+
+	want := []int{4, 8, 12}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := gen(1, 2, 3, 4, 5, 6)
+	outs := FanOut(ctx, 3, in, double)
+	merged := FanIn(ctx, outs...)
+
+	sum := 0
+	for n := range merged {
+		sum += n
+	}
+
+	if want := 2 * (1 + 2 + 3 + 4 + 5 + 6); sum != want {
+		t.Fatalf("sum = %d, want %d", sum, want)
+	}
+}
+
+func TestFanInCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	blocked := make(chan int)
+	merged := FanIn(ctx, blocked)
+
+	cancel()
+
+	select {
+	case _, ok := <-merged:
+		if ok {
+			t.Fatal("expected merged channel to be closed after cancellation")
+		}
+	}
+}